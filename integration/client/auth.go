@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// Authenticator applies authentication to an outgoing request, mutating it
+// in place (typically by setting a header) before it is sent.
+type Authenticator interface {
+	Apply(req *http.Request) error
+}
+
+// AuthOption sets the Authenticator used to sign every outgoing request,
+// replacing whichever one New derived from the token parameter.
+type AuthOption struct {
+	Authenticator Authenticator
+}
+
+func (o AuthOption) Type() string {
+	return "auth"
+}
+
+// basicAuth is an Authenticator that sets static HTTP basic auth
+// credentials on every request.
+type basicAuth struct {
+	user string
+	pass string
+}
+
+// BasicAuth returns an Authenticator that sets user/pass as HTTP basic auth
+// credentials on every request.
+func BasicAuth(user, pass string) Authenticator {
+	return &basicAuth{user: user, pass: pass}
+}
+
+func (a *basicAuth) Apply(req *http.Request) error {
+	req.SetBasicAuth(a.user, a.pass)
+	return nil
+}
+
+// bearerToken is an Authenticator that sets a static bearer token.
+type bearerToken struct {
+	token string
+}
+
+// BearerToken returns an Authenticator that sets tok as a static
+// `Authorization: Bearer` header on every request.
+func BearerToken(tok string) Authenticator {
+	return &bearerToken{token: tok}
+}
+
+func (a *bearerToken) Apply(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// JWTClaimsFunc builds the claims for a freshly minted token, given its
+// issued-at and expiry times.
+type JWTClaimsFunc func(issuedAt, expiresAt time.Time) jwt.Claims
+
+// JWTOption configures an Authenticator returned by JWTAuth.
+type JWTOption func(*jwtAuth)
+
+// WithJWTTTL overrides the default 5 minute lifetime of minted tokens.
+func WithJWTTTL(ttl time.Duration) JWTOption {
+	return func(a *jwtAuth) { a.ttl = ttl }
+}
+
+// jwtRefreshSkew is how far ahead of a cached token's expiry JWTAuth mints a
+// replacement, so a request never races an almost-expired token.
+const jwtRefreshSkew = 30 * time.Second
+
+// jwtAuth is an Authenticator that mints short-lived signed JWTs and caches
+// them until shortly before they expire.
+type jwtAuth struct {
+	signer   crypto.Signer
+	method   jwt.SigningMethod
+	claimsFn JWTClaimsFunc
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	cached  string
+	renewAt time.Time
+}
+
+// JWTAuth returns an Authenticator that mints a per-request bearer token by
+// signing claimsFn's output with signer, using RS256 for an RSA signer and
+// EdDSA for an Ed25519 signer. Tokens default to a 5 minute TTL (override
+// with WithJWTTTL) and are cached and reused until they are close to
+// expiring, at which point a replacement is minted automatically.
+func JWTAuth(signer crypto.Signer, claimsFn JWTClaimsFunc, opts ...JWTOption) Authenticator {
+	a := &jwtAuth{
+		signer:   signer,
+		method:   jwtSigningMethodFor(signer),
+		claimsFn: claimsFn,
+		ttl:      5 * time.Minute,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+func jwtSigningMethodFor(signer crypto.Signer) jwt.SigningMethod {
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+func (a *jwtAuth) Apply(req *http.Request) error {
+	tok, err := a.token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	return nil
+}
+
+func (a *jwtAuth) token() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if a.cached != "" && now.Before(a.renewAt) {
+		return a.cached, nil
+	}
+
+	issuedAt := now
+	expiresAt := now.Add(a.ttl)
+	tok, err := jwt.NewWithClaims(a.method, a.claimsFn(issuedAt, expiresAt)).SignedString(a.signer)
+	if err != nil {
+		return "", fmt.Errorf("signing JWT: %w", err)
+	}
+
+	a.cached = tok
+	a.renewAt = expiresAt.Add(-jwtRefreshSkew)
+	return tok, nil
+}
+
+// oauth2Auth is an Authenticator that attaches an OAuth2 access token
+// obtained and refreshed automatically via oauth2.TokenSource.
+type oauth2Auth struct {
+	ts oauth2.TokenSource
+}
+
+// OAuth2ClientCredentials returns an Authenticator that obtains an access
+// token from tokenURL using the OAuth2 client credentials grant and
+// refreshes it automatically as it nears expiry.
+func OAuth2ClientCredentials(tokenURL, clientID, clientSecret string, scopes ...string) Authenticator {
+	cfg := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     tokenURL,
+		Scopes:       scopes,
+	}
+	return &oauth2Auth{ts: cfg.TokenSource(context.Background())}
+}
+
+func (a *oauth2Auth) Apply(req *http.Request) error {
+	tok, err := a.ts.Token()
+	if err != nil {
+		return fmt.Errorf("fetching OAuth2 token: %w", err)
+	}
+	tok.SetAuthHeader(req)
+	return nil
+}
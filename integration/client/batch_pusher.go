@@ -0,0 +1,376 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BatchOptions configures a BatchPusher.
+type BatchOptions struct {
+	// MaxBatchBytes flushes the current batch once the accumulated line
+	// bytes reach this size.
+	MaxBatchBytes int
+	// MaxBatchAge flushes the current batch once its oldest entry is this
+	// old, regardless of size.
+	MaxBatchAge time.Duration
+	// MaxRetries bounds the number of retries for a failed flush. Zero
+	// means a flush is attempted exactly once.
+	MaxRetries int
+	// MinBackoff is the initial retry backoff.
+	MinBackoff time.Duration
+	// MaxBackoff caps the retry backoff.
+	MaxBackoff time.Duration
+}
+
+func (o BatchOptions) withDefaults() BatchOptions {
+	if o.MaxBatchBytes <= 0 {
+		o.MaxBatchBytes = 1 << 20 // 1MiB
+	}
+	if o.MaxBatchAge <= 0 {
+		o.MaxBatchAge = time.Second
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = 500 * time.Millisecond
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 5 * time.Second
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	return o
+}
+
+type batchEntry struct {
+	ts   time.Time
+	line string
+}
+
+type batchStream struct {
+	labels  map[string]string
+	entries []batchEntry
+}
+
+// BatchPusher accumulates log entries in memory, grouped by label set, and
+// periodically flushes them to Loki as a single multi-stream push, retrying
+// failed flushes with exponential backoff.
+type BatchPusher struct {
+	c    *Client
+	opts BatchOptions
+
+	mu          sync.Mutex
+	streams     map[uint64]*batchStream
+	batchBytes  int
+	oldestEntry time.Time
+
+	flushNow chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	pushedEntriesTotal  prometheus.Counter
+	droppedEntriesTotal prometheus.Counter
+	retriesTotal        prometheus.Counter
+}
+
+// NewBatchPusher creates a BatchPusher on top of c and starts its background
+// flush loop. Callers must call Stop to flush any remaining entries and stop
+// the loop.
+func NewBatchPusher(c *Client, opts BatchOptions) *BatchPusher {
+	p := &BatchPusher{
+		c:        c,
+		opts:     opts.withDefaults(),
+		streams:  make(map[uint64]*batchStream),
+		flushNow: make(chan struct{}, 1),
+		stopped:  make(chan struct{}),
+		done:     make(chan struct{}),
+
+		pushedEntriesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "pushed_entries_total",
+			Help: "Total number of log entries successfully pushed.",
+		}),
+		droppedEntriesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dropped_entries_total",
+			Help: "Total number of log entries dropped after exhausting retries.",
+		}),
+		retriesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "retries_total",
+			Help: "Total number of retried push attempts.",
+		}),
+	}
+
+	go p.loop()
+	return p
+}
+
+// Collector returns a prometheus.Collector exposing the pusher's counters,
+// ready for the caller to register.
+func (p *BatchPusher) Collector() prometheus.Collector {
+	return multiCollector{p.pushedEntriesTotal, p.droppedEntriesTotal, p.retriesTotal}
+}
+
+// multiCollector bundles several prometheus.Collectors behind a single
+// Collector so BatchPusher can expose its counters with one registration.
+type multiCollector []prometheus.Collector
+
+func (m multiCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m {
+		c.Describe(ch)
+	}
+}
+
+func (m multiCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m {
+		c.Collect(ch)
+	}
+}
+
+// Enqueue adds a log line for labels at timestamp ts to the current batch,
+// flushing immediately if the batch has grown past MaxBatchBytes.
+func (p *BatchPusher) Enqueue(labels map[string]string, ts time.Time, line string) {
+	fp := labelsFingerprint(labels)
+
+	p.mu.Lock()
+	s, ok := p.streams[fp]
+	if !ok {
+		// Copy labels: the caller may reuse/mutate the map it passed in
+		// across subsequent Enqueue calls.
+		labelsCopy := make(map[string]string, len(labels))
+		for k, v := range labels {
+			labelsCopy[k] = v
+		}
+		s = &batchStream{labels: labelsCopy}
+		p.streams[fp] = s
+	}
+	s.entries = append(s.entries, batchEntry{ts: ts, line: line})
+
+	p.batchBytes += len(line)
+	if p.oldestEntry.IsZero() {
+		p.oldestEntry = time.Now()
+	}
+	full := p.batchBytes >= p.opts.MaxBatchBytes
+	p.mu.Unlock()
+
+	if full {
+		select {
+		case p.flushNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Stop flushes any remaining entries and stops the background flush loop.
+// Safe to call more than once; only the first call performs the flush.
+func (p *BatchPusher) Stop(ctx context.Context) error {
+	var flushErr error
+	p.stopOnce.Do(func() {
+		close(p.stopped)
+		<-p.done
+		flushErr = p.flush(ctx)
+	})
+	return flushErr
+}
+
+func (p *BatchPusher) loop() {
+	defer close(p.done)
+
+	// Poll more often than MaxBatchAge so a flush fires promptly once the
+	// oldest buffered entry actually reaches that age, rather than on a
+	// fixed schedule unrelated to how long anything has been buffered.
+	pollInterval := p.opts.MaxBatchAge / 4
+	if pollInterval <= 0 {
+		pollInterval = time.Millisecond
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if p.oldestEntryAge() >= p.opts.MaxBatchAge {
+				_ = p.flush(context.Background())
+			}
+		case <-p.flushNow:
+			_ = p.flush(context.Background())
+		case <-p.stopped:
+			return
+		}
+	}
+}
+
+// oldestEntryAge returns how long the oldest entry in the current batch has
+// been buffered, or zero if the batch is empty.
+func (p *BatchPusher) oldestEntryAge() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oldestEntry.IsZero() {
+		return 0
+	}
+	return time.Since(p.oldestEntry)
+}
+
+// flush sends the current batch as a single multi-stream push and clears it,
+// regardless of whether the push ultimately succeeds.
+func (p *BatchPusher) flush(ctx context.Context) error {
+	p.mu.Lock()
+	if len(p.streams) == 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	streams := p.streams
+	entryCount := 0
+	for _, s := range streams {
+		entryCount += len(s.entries)
+	}
+	p.streams = make(map[uint64]*batchStream)
+	p.batchBytes = 0
+	p.oldestEntry = time.Time{}
+	p.mu.Unlock()
+
+	body, err := json.Marshal(&struct {
+		Streams []stream `json:"streams"`
+	}{
+		Streams: toJSONStreams(streams),
+	})
+	if err != nil {
+		p.droppedEntriesTotal.Add(float64(entryCount))
+		return err
+	}
+
+	if err := p.pushWithRetry(ctx, body); err != nil {
+		p.droppedEntriesTotal.Add(float64(entryCount))
+		return err
+	}
+
+	p.pushedEntriesTotal.Add(float64(entryCount))
+	return nil
+}
+
+func toJSONStreams(streams map[uint64]*batchStream) []stream {
+	out := make([]stream, 0, len(streams))
+	for _, s := range streams {
+		values := make([][]string, 0, len(s.entries))
+		for _, e := range s.entries {
+			values = append(values, []string{formatTS(e.ts), e.line})
+		}
+		out = append(out, stream{Stream: s.labels, Values: values})
+	}
+	return out
+}
+
+// pushWithRetry POSTs body to the push endpoint, retrying on 429/503 with
+// exponential backoff and jitter, honoring Retry-After when present.
+func (p *BatchPusher) pushWithRetry(ctx context.Context, body []byte) error {
+	apiEndpoint := fmt.Sprintf("%s/loki/api/v1/push", p.c.baseURL)
+	backoff := p.opts.MinBackoff
+
+	var lastErr error
+	for attempt := 0; attempt <= p.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			p.retriesTotal.Inc()
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", apiEndpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Scope-OrgID", p.c.instanceID)
+
+		res, err := p.c.httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+		} else {
+			retryable := res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable
+			wait := jitter(backoff)
+			if retryable {
+				if ra := parseRetryAfter(res.Header.Get("Retry-After")); ra > 0 {
+					wait = ra
+				}
+			}
+			res.Body.Close()
+
+			if res.StatusCode/100 == 2 {
+				return nil
+			}
+			if !retryable {
+				return fmt.Errorf("request failed with status code %d", res.StatusCode)
+			}
+			lastErr = fmt.Errorf("request failed with status code %d", res.StatusCode)
+
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			backoff *= 2
+			if backoff > p.opts.MaxBackoff {
+				backoff = p.opts.MaxBackoff
+			}
+			continue
+		}
+
+		select {
+		case <-time.After(jitter(backoff)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+		if backoff > p.opts.MaxBackoff {
+			backoff = p.opts.MaxBackoff
+		}
+	}
+
+	return lastErr
+}
+
+func parseRetryAfter(h string) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(h); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := time.Parse(time.RFC1123, h); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
+
+// labelsFingerprint hashes a sorted "k=v" encoding of labels with FNV-1a,
+// giving a stable key to group entries into the same stream.
+func labelsFingerprint(labels map[string]string) uint64 {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := fnv.New64a()
+	for _, k := range keys {
+		_, _ = h.Write([]byte(k))
+		_, _ = h.Write([]byte("="))
+		_, _ = h.Write([]byte(labels[k]))
+		_, _ = h.Write([]byte(","))
+	}
+	return h.Sum64()
+}
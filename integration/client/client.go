@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,28 +11,29 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 type roundTripper struct {
 	instanceID    string
-	token         string
+	auth          Authenticator
 	injectHeaders map[string][]string
 	next          http.RoundTripper
 }
 
 func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.Header.Set("X-Scope-OrgID", r.instanceID)
-	if r.token != "" {
-		req.SetBasicAuth(r.instanceID, r.token)
+	if r.auth != nil {
+		if err := r.auth.Apply(req); err != nil {
+			return nil, fmt.Errorf("applying authenticator: %w", err)
+		}
 	}
 
 	for key, values := range r.injectHeaders {
 		for _, v := range values {
 			req.Header.Add(key, v)
 		}
-
-		fmt.Println(req.Header.Values(key))
 	}
 
 	return r.next.RoundTrip(req)
@@ -54,42 +56,129 @@ type Client struct {
 	httpClient *http.Client
 	baseURL    string
 	instanceID string
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+
+	pushFormat PushFormat
+}
+
+// SetReadDeadline sets the absolute deadline applied to the context of
+// read-only calls (queries, label lookups, metrics, ...) that don't already
+// carry their own deadline. A zero time.Time clears the deadline.
+func (c *Client) SetReadDeadline(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+}
+
+// SetWriteDeadline sets the absolute deadline applied to the context of
+// write calls (pushing log lines, flushing) that don't already carry their
+// own deadline. A zero time.Time clears the deadline.
+func (c *Client) SetWriteDeadline(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeDeadline = t
+}
+
+// withReadDeadline wraps ctx with the configured read deadline, if any, and
+// returns the (possibly unchanged) context along with its cancel func.
+func (c *Client) withReadDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	c.mu.Lock()
+	deadline := c.readDeadline
+	c.mu.Unlock()
+
+	if deadline.IsZero() {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// withWriteDeadline wraps ctx with the configured write deadline, if any, and
+// returns the (possibly unchanged) context along with its cancel func.
+func (c *Client) withWriteDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	c.mu.Lock()
+	deadline := c.writeDeadline
+	c.mu.Unlock()
+
+	if deadline.IsZero() {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline)
 }
 
 // NewLogsClient creates a new client
 func New(instanceID, token, baseURL string, opts ...CortexClientOption) *Client {
 	rt := &roundTripper{
 		instanceID: instanceID,
-		token:      token,
 		next:       http.DefaultTransport,
 	}
-
-	for _, opt := range opts {
-		switch opt.Type() {
-		case "headerinject":
-			rt.injectHeaders = opt.(InjectHeadersOption)
-		}
+	// The token parameter is syntactic sugar for BasicAuth; pass an
+	// AuthOption to use a different Authenticator.
+	if token != "" {
+		rt.auth = BasicAuth(instanceID, token)
 	}
 
-	return &Client{
+	c := &Client{
 		Now: time.Now(),
 		httpClient: &http.Client{
 			Transport: rt,
 		},
 		baseURL:    baseURL,
 		instanceID: instanceID,
+		pushFormat: PushFormatJSON,
+	}
+
+	for _, opt := range opts {
+		switch opt.Type() {
+		case "headerinject":
+			rt.injectHeaders = opt.(InjectHeadersOption)
+		case "pushformat":
+			c.pushFormat = opt.(PushFormatOption).Format
+		case "auth":
+			rt.auth = opt.(AuthOption).Authenticator
+		}
 	}
+
+	return c
 }
 
 // PushLogLine creates a new logline with the current time as timestamp
 func (c *Client) PushLogLine(line string, extraLabels ...map[string]string) error {
-	return c.pushLogLine(line, c.Now, extraLabels...)
+	return c.PushLogLineContext(context.Background(), line, extraLabels...)
+}
+
+// PushLogLineContext creates a new logline with the current time as
+// timestamp, aborting if ctx is done before the request completes.
+func (c *Client) PushLogLineContext(ctx context.Context, line string, extraLabels ...map[string]string) error {
+	return c.pushLogLine(ctx, line, c.Now, nil, extraLabels...)
 }
 
 // PushLogLineWithTimestamp creates a new logline at the given timestamp
 // The timestamp has to be a Unix timestamp (epoch seconds)
 func (c *Client) PushLogLineWithTimestamp(line string, timestamp time.Time, extraLabelList ...map[string]string) error {
-	return c.pushLogLine(line, timestamp, extraLabelList...)
+	return c.PushLogLineWithTimestampContext(context.Background(), line, timestamp, extraLabelList...)
+}
+
+// PushLogLineWithTimestampContext creates a new logline at the given
+// timestamp, aborting if ctx is done before the request completes.
+func (c *Client) PushLogLineWithTimestampContext(ctx context.Context, line string, timestamp time.Time, extraLabelList ...map[string]string) error {
+	return c.pushLogLine(ctx, line, timestamp, nil, extraLabelList...)
+}
+
+// PushLogLineWithMetadata creates a new logline at the given timestamp
+// carrying structured metadata. Structured metadata is only transmitted
+// when the client is configured with PushFormatProtobuf; it is silently
+// dropped for PushFormatJSON.
+func (c *Client) PushLogLineWithMetadata(line string, timestamp time.Time, metadata map[string]string, extraLabelList ...map[string]string) error {
+	return c.PushLogLineWithMetadataContext(context.Background(), line, timestamp, metadata, extraLabelList...)
+}
+
+// PushLogLineWithMetadataContext behaves like PushLogLineWithMetadata but
+// aborts if ctx is done before the request completes.
+func (c *Client) PushLogLineWithMetadataContext(ctx context.Context, line string, timestamp time.Time, metadata map[string]string, extraLabelList ...map[string]string) error {
+	return c.pushLogLine(ctx, line, timestamp, metadata, extraLabelList...)
 }
 
 func formatTS(ts time.Time) string {
@@ -102,40 +191,67 @@ type stream struct {
 }
 
 // pushLogLine creates a new logline
-func (c *Client) pushLogLine(line string, timestamp time.Time, extraLabelList ...map[string]string) error {
+func (c *Client) pushLogLine(ctx context.Context, line string, timestamp time.Time, metadata map[string]string, extraLabelList ...map[string]string) error {
+	ctx, cancel := c.withWriteDeadline(ctx)
+	defer cancel()
+
 	apiEndpoint := fmt.Sprintf("%s/loki/api/v1/push", c.baseURL)
 
-	s := stream{
-		Stream: map[string]string{
-			"job": "varlog",
-		},
-		Values: [][]string{
-			{
-				formatTS(timestamp),
-				line,
-			},
-		},
+	labels := map[string]string{
+		"job": "varlog",
 	}
 	// add extra labels
 	for _, labelList := range extraLabelList {
 		for k, v := range labelList {
-			s.Stream[k] = v
+			labels[k] = v
 		}
 	}
 
-	data, err := json.Marshal(&struct {
-		Streams []stream `json:"streams"`
-	}{
-		Streams: []stream{s},
-	})
-	if err != nil {
-		return err
+	var (
+		body        []byte
+		contentType string
+		encoding    string
+	)
+	switch c.pushFormat {
+	case PushFormatProtobuf:
+		data, err := marshalProtobufPush(labels, timestamp, line, metadata)
+		if err != nil {
+			return err
+		}
+		body = data
+		contentType = "application/x-protobuf"
+		encoding = "snappy"
+	default:
+		s := stream{
+			Stream: labels,
+			Values: [][]string{
+				{
+					formatTS(timestamp),
+					line,
+				},
+			},
+		}
+
+		data, err := json.Marshal(&struct {
+			Streams []stream `json:"streams"`
+		}{
+			Streams: []stream{s},
+		})
+		if err != nil {
+			return err
+		}
+		body = data
+		contentType = "application/json"
 	}
-	req, err := http.NewRequest("POST", apiEndpoint, bytes.NewReader(data))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiEndpoint, bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
 	req.Header.Set("X-Scope-OrgID", c.instanceID)
 
 	// Execute HTTP request
@@ -158,8 +274,17 @@ func (c *Client) pushLogLine(line string, timestamp time.Time, extraLabelList ..
 }
 
 func (c *Client) Get(path string) (*http.Response, error) {
+	return c.GetContext(context.Background(), path)
+}
+
+// GetContext behaves like Get but aborts if ctx is done before the request
+// completes.
+func (c *Client) GetContext(ctx context.Context, path string) (*http.Response, error) {
+	ctx, cancel := c.withReadDeadline(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s%s", c.baseURL, path)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -168,8 +293,21 @@ func (c *Client) Get(path string) (*http.Response, error) {
 
 // Get all the metrics
 func (c *Client) Metrics() (string, error) {
+	return c.MetricsContext(context.Background())
+}
+
+// MetricsContext behaves like Metrics but aborts if ctx is done before the
+// request completes.
+func (c *Client) MetricsContext(ctx context.Context) (string, error) {
+	ctx, cancel := c.withReadDeadline(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/metrics", c.baseURL)
-	res, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -187,7 +325,16 @@ func (c *Client) Metrics() (string, error) {
 
 // Flush all in-memory chunks held by the ingesters to the backing store
 func (c *Client) Flush() error {
-	req, err := c.request("POST", fmt.Sprintf("%s/flush", c.baseURL))
+	return c.FlushContext(context.Background())
+}
+
+// FlushContext behaves like Flush but aborts if ctx is done before the
+// request completes.
+func (c *Client) FlushContext(ctx context.Context) error {
+	ctx, cancel := c.withWriteDeadline(ctx)
+	defer cancel()
+
+	req, err := c.requestContext(ctx, "POST", fmt.Sprintf("%s/flush", c.baseURL))
 	if err != nil {
 		return err
 	}
@@ -290,70 +437,19 @@ type Response struct {
 	Data   DataType
 }
 
-// RunRangeQuery runs a query and returns an error if anything went wrong
-func (c *Client) RunRangeQuery(query string) (*Response, error) {
-	buf, statusCode, err := c.run(c.rangeQueryURL(query))
-	if err != nil {
-		return nil, err
-	}
-
-	return c.parseResponse(buf, statusCode)
-}
-
-// RunQuery runs a query and returns an error if anything went wrong
-func (c *Client) RunQuery(query string) (*Response, error) {
-	v := url.Values{}
-	v.Set("query", query)
-	v.Set("time", formatTS(c.Now.Add(time.Second)))
-
-	u, err := url.Parse(c.baseURL)
-	if err != nil {
-		return nil, err
-	}
-	u.Path = "/loki/api/v1/query"
-	u.RawQuery = v.Encode()
-
-	buf, statusCode, err := c.run(u.String())
-	if err != nil {
-		return nil, err
-	}
-
-	return c.parseResponse(buf, statusCode)
-}
-
-func (c *Client) parseResponse(buf []byte, statusCode int) (*Response, error) {
-	lokiResp := Response{}
-	err := json.Unmarshal(buf, &lokiResp)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing response data: %w", err)
-	}
-
-	if statusCode/100 == 2 {
-		return &lokiResp, nil
-	}
-	return nil, fmt.Errorf("request failed with status code %d: %w", statusCode, errors.New(string(buf)))
+func (c *Client) LabelNames() ([]string, error) {
+	return c.LabelNamesContext(context.Background())
 }
 
-func (c *Client) rangeQueryURL(query string) string {
-	v := url.Values{}
-	v.Set("query", query)
-	v.Set("start", formatTS(c.Now.Add(-2*time.Hour)))
-	v.Set("end", formatTS(c.Now.Add(time.Second)))
-
-	u, err := url.Parse(c.baseURL)
-	if err != nil {
-		panic(err)
-	}
-	u.Path = "/loki/api/v1/query_range"
-	u.RawQuery = v.Encode()
-
-	return u.String()
-}
+// LabelNamesContext behaves like LabelNames but aborts if ctx is done before
+// the request completes.
+func (c *Client) LabelNamesContext(ctx context.Context) ([]string, error) {
+	ctx, cancel := c.withReadDeadline(ctx)
+	defer cancel()
 
-func (c *Client) LabelNames() ([]string, error) {
 	url := fmt.Sprintf("%s/loki/api/v1/labels", c.baseURL)
 
-	req, err := c.request("GET", url)
+	req, err := c.requestContext(ctx, "GET", url)
 	if err != nil {
 		return nil, err
 	}
@@ -380,9 +476,18 @@ func (c *Client) LabelNames() ([]string, error) {
 
 // LabelValues return a LabelValues query
 func (c *Client) LabelValues(labelName string) ([]string, error) {
+	return c.LabelValuesContext(context.Background(), labelName)
+}
+
+// LabelValuesContext behaves like LabelValues but aborts if ctx is done
+// before the request completes.
+func (c *Client) LabelValuesContext(ctx context.Context, labelName string) ([]string, error) {
+	ctx, cancel := c.withReadDeadline(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/loki/api/v1/label/%s/values", c.baseURL, url.PathEscape(labelName))
 
-	req, err := c.request("GET", url)
+	req, err := c.requestContext(ctx, "GET", url)
 	if err != nil {
 		return nil, err
 	}
@@ -407,8 +512,8 @@ func (c *Client) LabelValues(labelName string) ([]string, error) {
 	return values.Data, nil
 }
 
-func (c *Client) request(method string, url string) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, nil)
+func (c *Client) requestContext(ctx context.Context, method string, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -416,8 +521,11 @@ func (c *Client) request(method string, url string) (*http.Request, error) {
 	return req, nil
 }
 
-func (c *Client) run(u string) ([]byte, int, error) {
-	req, err := c.request("GET", u)
+func (c *Client) run(ctx context.Context, u string) ([]byte, int, error) {
+	ctx, cancel := c.withReadDeadline(ctx)
+	defer cancel()
+
+	req, err := c.requestContext(ctx, "GET", u)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,331 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// formatDuration renders d as a plain number of seconds, e.g. "90" or "1.5",
+// which Loki and Prometheus accept as a float number of seconds.
+// time.Duration.String() instead produces forms like "1.5s" or "100µs" that
+// their duration parsers reject.
+func formatDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// QueryOptions configures an instant query against /loki/api/v1/query,
+// exposing the full parameter surface of the endpoint.
+type QueryOptions struct {
+	// Time is the evaluation instant. Defaults to Client.Now plus one
+	// second, matching the historical RunQuery behaviour.
+	Time time.Time
+	// Direction controls the order log lines are returned in: "forward"
+	// (oldest first) or "backward" (newest first, the API default).
+	Direction string
+	// Limit caps the number of entries or series returned.
+	Limit int
+	// Since evaluates the query at now minus Since instead of at an
+	// absolute Time. Ignored if Time is set.
+	Since time.Duration
+}
+
+func (o QueryOptions) withDefaults(now time.Time) QueryOptions {
+	if o.Time.IsZero() {
+		if o.Since > 0 {
+			o.Time = now.Add(-o.Since)
+		} else {
+			o.Time = now.Add(time.Second)
+		}
+	}
+	return o
+}
+
+func (o QueryOptions) values(query string) url.Values {
+	v := url.Values{}
+	v.Set("query", query)
+	v.Set("time", formatTS(o.Time))
+	if o.Direction != "" {
+		v.Set("direction", o.Direction)
+	}
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return v
+}
+
+// RangeQueryOptions configures a range query against
+// /loki/api/v1/query_range, exposing the full parameter surface of the
+// endpoint.
+type RangeQueryOptions struct {
+	// Start and End bound the query range. Default to Client.Now minus
+	// two hours and Client.Now plus one second, matching the historical
+	// RunRangeQuery behaviour.
+	Start, End time.Time
+	// Step is the query resolution for metric queries.
+	Step time.Duration
+	// Interval downsamples log queries to one entry per Interval.
+	Interval time.Duration
+	// Direction controls the order log lines are returned in: "forward"
+	// (oldest first) or "backward" (newest first, the API default).
+	Direction string
+	// Limit caps the number of entries or series returned.
+	Limit int
+	// Since sets Start to now minus Since. Ignored if Start is set.
+	Since time.Duration
+}
+
+func (o RangeQueryOptions) withDefaults(now time.Time) RangeQueryOptions {
+	if o.End.IsZero() {
+		o.End = now.Add(time.Second)
+	}
+	if o.Start.IsZero() {
+		if o.Since > 0 {
+			o.Start = now.Add(-o.Since)
+		} else {
+			o.Start = now.Add(-2 * time.Hour)
+		}
+	}
+	return o
+}
+
+func (o RangeQueryOptions) values(query string) url.Values {
+	v := url.Values{}
+	v.Set("query", query)
+	v.Set("start", formatTS(o.Start))
+	v.Set("end", formatTS(o.End))
+	if o.Step > 0 {
+		v.Set("step", formatDuration(o.Step))
+	}
+	if o.Interval > 0 {
+		v.Set("interval", formatDuration(o.Interval))
+	}
+	if o.Direction != "" {
+		v.Set("direction", o.Direction)
+	}
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return v
+}
+
+// RunRangeQuery runs a query and returns an error if anything went wrong
+func (c *Client) RunRangeQuery(query string) (*Response, error) {
+	return c.RunRangeQueryContext(context.Background(), query)
+}
+
+// RunRangeQueryContext behaves like RunRangeQuery but aborts if ctx is done
+// before the request completes.
+func (c *Client) RunRangeQueryContext(ctx context.Context, query string) (*Response, error) {
+	return c.RunRangeQueryWithOptions(ctx, query, RangeQueryOptions{})
+}
+
+// RunRangeQueryWithOptions runs a range query against
+// /loki/api/v1/query_range with the given opts, aborting if ctx is done
+// before the request completes.
+func (c *Client) RunRangeQueryWithOptions(ctx context.Context, query string, opts RangeQueryOptions) (*Response, error) {
+	opts = opts.withDefaults(c.Now)
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/loki/api/v1/query_range"
+	u.RawQuery = opts.values(query).Encode()
+
+	buf, statusCode, err := c.run(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parseResponse(buf, statusCode)
+}
+
+// RunQuery runs a query and returns an error if anything went wrong
+func (c *Client) RunQuery(query string) (*Response, error) {
+	return c.RunQueryContext(context.Background(), query)
+}
+
+// RunQueryContext behaves like RunQuery but aborts if ctx is done before the
+// request completes.
+func (c *Client) RunQueryContext(ctx context.Context, query string) (*Response, error) {
+	return c.RunQueryWithOptions(ctx, query, QueryOptions{})
+}
+
+// RunQueryWithOptions runs an instant query against /loki/api/v1/query with
+// the given opts, aborting if ctx is done before the request completes.
+func (c *Client) RunQueryWithOptions(ctx context.Context, query string, opts QueryOptions) (*Response, error) {
+	opts = opts.withDefaults(c.Now)
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/loki/api/v1/query"
+	u.RawQuery = opts.values(query).Encode()
+
+	buf, statusCode, err := c.run(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parseResponse(buf, statusCode)
+}
+
+func (c *Client) parseResponse(buf []byte, statusCode int) (*Response, error) {
+	lokiResp := Response{}
+	err := json.Unmarshal(buf, &lokiResp)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing response data: %w", err)
+	}
+
+	if statusCode/100 == 2 {
+		return &lokiResp, nil
+	}
+	return nil, fmt.Errorf("request failed with status code %d: %w", statusCode, errors.New(string(buf)))
+}
+
+// Series returns the set of label sets that matched the given stream
+// selectors within [start, end] from /loki/api/v1/series.
+func (c *Client) Series(ctx context.Context, matchers []string, start, end time.Time) ([]map[string]string, error) {
+	v := url.Values{}
+	for _, m := range matchers {
+		v.Add("match[]", m)
+	}
+	if !start.IsZero() {
+		v.Set("start", formatTS(start))
+	}
+	if !end.IsZero() {
+		v.Set("end", formatTS(end))
+	}
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/loki/api/v1/series"
+	u.RawQuery = v.Encode()
+
+	buf, statusCode, err := c.run(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	if statusCode/100 != 2 {
+		return nil, fmt.Errorf("request failed with status code %d: %w", statusCode, errors.New(string(buf)))
+	}
+
+	var result struct {
+		Data []map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, fmt.Errorf("error parsing series response: %w", err)
+	}
+	return result.Data, nil
+}
+
+// IndexStats summarizes the chunks the index holds for a stream selector
+// over a time range, as returned by /loki/api/v1/index/stats.
+type IndexStats struct {
+	Streams uint64 `json:"streams"`
+	Chunks  uint64 `json:"chunks"`
+	Entries uint64 `json:"entries"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+// IndexStats fetches index statistics for query over [start, end] from
+// /loki/api/v1/index/stats.
+func (c *Client) IndexStats(ctx context.Context, query string, start, end time.Time) (*IndexStats, error) {
+	v := url.Values{}
+	v.Set("query", query)
+	v.Set("start", formatTS(start))
+	v.Set("end", formatTS(end))
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/loki/api/v1/index/stats"
+	u.RawQuery = v.Encode()
+
+	buf, statusCode, err := c.run(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	if statusCode/100 != 2 {
+		return nil, fmt.Errorf("request failed with status code %d: %w", statusCode, errors.New(string(buf)))
+	}
+
+	var stats IndexStats
+	if err := json.Unmarshal(buf, &stats); err != nil {
+		return nil, fmt.Errorf("error parsing index stats response: %w", err)
+	}
+	return &stats, nil
+}
+
+// Volume is the log volume, in bytes, for a single label set over the
+// queried range.
+type Volume struct {
+	Metric map[string]string `json:"metric"`
+	Value  string            `json:"value"`
+}
+
+// VolumeResponse is the response body of /loki/api/v1/index/volume and
+// /loki/api/v1/index/volume_range.
+type VolumeResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string   `json:"resultType"`
+		Result     []Volume `json:"result"`
+	} `json:"data"`
+}
+
+// IndexVolume fetches aggregate log volume for query over [start, end] from
+// /loki/api/v1/index/volume. limit caps the number of series returned; pass
+// 0 to use the server default.
+func (c *Client) IndexVolume(ctx context.Context, query string, start, end time.Time, limit int) (*VolumeResponse, error) {
+	return c.indexVolume(ctx, "/loki/api/v1/index/volume", query, start, end, 0, limit)
+}
+
+// IndexVolumeRange behaves like IndexVolume but returns volume bucketed
+// into step-sized ranges from /loki/api/v1/index/volume_range.
+func (c *Client) IndexVolumeRange(ctx context.Context, query string, start, end time.Time, step time.Duration, limit int) (*VolumeResponse, error) {
+	return c.indexVolume(ctx, "/loki/api/v1/index/volume_range", query, start, end, step, limit)
+}
+
+func (c *Client) indexVolume(ctx context.Context, path, query string, start, end time.Time, step time.Duration, limit int) (*VolumeResponse, error) {
+	v := url.Values{}
+	if step > 0 {
+		v.Set("step", formatDuration(step))
+	}
+	v.Set("query", query)
+	v.Set("start", formatTS(start))
+	v.Set("end", formatTS(end))
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path
+	u.RawQuery = v.Encode()
+
+	buf, statusCode, err := c.run(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	if statusCode/100 != 2 {
+		return nil, fmt.Errorf("request failed with status code %d: %w", statusCode, errors.New(string(buf)))
+	}
+
+	var result VolumeResponse
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, fmt.Errorf("error parsing index volume response: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RulesOptions filters the /prometheus/api/v1/rules listing.
+type RulesOptions struct {
+	// Type restricts results to "alert" or "record" rules.
+	Type string
+	// RuleName restricts results to rules with this name.
+	RuleName string
+	// RuleGroup restricts results to this rule group.
+	RuleGroup string
+	// File restricts results to rules loaded from this file/namespace.
+	File string
+}
+
+// RecordingRule mirrors the JSON shape Loki's ruler returns for recording
+// rules under /prometheus/api/v1/rules.
+type RecordingRule struct {
+	Name           string            `json:"name"`
+	Query          string            `json:"query"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Health         string            `json:"health"`
+	LastError      string            `json:"lastError,omitempty"`
+	EvaluationTime float64           `json:"evaluationTime"`
+	LastEvaluation time.Time         `json:"lastEvaluation"`
+	Type           string            `json:"type"`
+}
+
+// Alert is a single firing/pending alert instance.
+type Alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state"`
+	ActiveAt    *time.Time        `json:"activeAt,omitempty"`
+	Value       string            `json:"value"`
+}
+
+// AlertingRule mirrors the JSON shape Loki's ruler returns for alerting
+// rules under /prometheus/api/v1/rules.
+type AlertingRule struct {
+	Name           string            `json:"name"`
+	Query          string            `json:"query"`
+	Duration       float64           `json:"duration"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+	Alerts         []Alert           `json:"alerts"`
+	Health         string            `json:"health"`
+	LastError      string            `json:"lastError,omitempty"`
+	EvaluationTime float64           `json:"evaluationTime"`
+	LastEvaluation time.Time         `json:"lastEvaluation"`
+	State          string            `json:"state"`
+	Type           string            `json:"type"`
+}
+
+// Rule is either an AlertingRule or a RecordingRule, discriminated by Type,
+// matching the mixed "rules" array Loki returns per group.
+type Rule struct {
+	Type      string
+	Alerting  *AlertingRule
+	Recording *RecordingRule
+}
+
+func (r *Rule) UnmarshalJSON(b []byte) error {
+	var disc struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(b, &disc); err != nil {
+		return err
+	}
+
+	switch disc.Type {
+	case "alerting":
+		var a AlertingRule
+		if err := json.Unmarshal(b, &a); err != nil {
+			return err
+		}
+		r.Alerting = &a
+	case "recording":
+		var rr RecordingRule
+		if err := json.Unmarshal(b, &rr); err != nil {
+			return err
+		}
+		r.Recording = &rr
+	default:
+		return fmt.Errorf("unknown rule type %s", disc.Type)
+	}
+	r.Type = disc.Type
+	return nil
+}
+
+// RuleGroup is a group of evaluated rules as returned by
+// /prometheus/api/v1/rules.
+type RuleGroup struct {
+	Name           string    `json:"name"`
+	File           string    `json:"file"`
+	Rules          []Rule    `json:"rules"`
+	Interval       float64   `json:"interval"`
+	LastEvaluation time.Time `json:"lastEvaluation"`
+	EvaluationTime float64   `json:"evaluationTime"`
+}
+
+// RulesResult is the response body of /prometheus/api/v1/rules.
+type RulesResult struct {
+	Status string `json:"status"`
+	Data   struct {
+		Groups []RuleGroup `json:"groups"`
+	} `json:"data"`
+}
+
+// AlertsResult is the response body of /prometheus/api/v1/alerts.
+type AlertsResult struct {
+	Status string `json:"status"`
+	Data   struct {
+		Alerts []Alert `json:"alerts"`
+	} `json:"data"`
+}
+
+// Rules fetches the rule groups known to the ruler, optionally filtered by
+// opts, from /prometheus/api/v1/rules.
+func (c *Client) Rules(ctx context.Context, opts RulesOptions) (*RulesResult, error) {
+	v := url.Values{}
+	if opts.Type != "" {
+		v.Set("type", opts.Type)
+	}
+	if opts.RuleName != "" {
+		v.Set("rule_name", opts.RuleName)
+	}
+	if opts.RuleGroup != "" {
+		v.Set("rule_group", opts.RuleGroup)
+	}
+	if opts.File != "" {
+		v.Set("file", opts.File)
+	}
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/prometheus/api/v1/rules"
+	u.RawQuery = v.Encode()
+
+	buf, statusCode, err := c.run(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode/100 != 2 {
+		return nil, fmt.Errorf("request failed with status code %d: %w", statusCode, errors.New(string(buf)))
+	}
+
+	var result RulesResult
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, fmt.Errorf("error parsing rules response: %w", err)
+	}
+	return &result, nil
+}
+
+// Alerts fetches all active alerts from /prometheus/api/v1/alerts.
+func (c *Client) Alerts(ctx context.Context) (*AlertsResult, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/prometheus/api/v1/alerts"
+
+	buf, statusCode, err := c.run(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode/100 != 2 {
+		return nil, fmt.Errorf("request failed with status code %d: %w", statusCode, errors.New(string(buf)))
+	}
+
+	var result AlertsResult
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, fmt.Errorf("error parsing alerts response: %w", err)
+	}
+	return &result, nil
+}
+
+// RuleConfig is a single rule within a RuleGroupConfig, as accepted by the
+// ruler's namespace/group config API.
+type RuleConfig struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         time.Duration     `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// RuleGroupConfig is the YAML configuration of a rule group as stored by
+// the ruler, distinct from the evaluated RuleGroup returned by Rules.
+type RuleGroupConfig struct {
+	Name     string        `yaml:"name"`
+	Interval time.Duration `yaml:"interval,omitempty"`
+	Rules    []RuleConfig  `yaml:"rules"`
+}
+
+// GetRuleGroup fetches a single rule group's YAML config from
+// /loki/api/v1/rules/{namespace}/{group}.
+func (c *Client) GetRuleGroup(ctx context.Context, namespace, group string) (*RuleGroupConfig, error) {
+	path := fmt.Sprintf("/loki/api/v1/rules/%s/%s", url.PathEscape(namespace), url.PathEscape(group))
+
+	res, err := c.GetContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("request failed with status code %d: %w", res.StatusCode, errors.New(string(buf)))
+	}
+
+	var cfg RuleGroupConfig
+	if err := yaml.Unmarshal(buf, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing rule group config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// SetRuleGroup creates or replaces a rule group by POSTing its YAML config
+// to /loki/api/v1/rules/{namespace}.
+func (c *Client) SetRuleGroup(ctx context.Context, namespace string, group RuleGroupConfig) error {
+	data, err := yaml.Marshal(group)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withWriteDeadline(ctx)
+	defer cancel()
+
+	apiEndpoint := fmt.Sprintf("%s/loki/api/v1/rules/%s", c.baseURL, url.PathEscape(namespace))
+	req, err := http.NewRequestWithContext(ctx, "POST", apiEndpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/yaml")
+	req.Header.Set("X-Scope-OrgID", c.instanceID)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 == 2 {
+		return nil
+	}
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("reading request failed with status code %v: %w", res.StatusCode, err)
+	}
+	return fmt.Errorf("request failed with status code %v: %w", res.StatusCode, errors.New(string(buf)))
+}
+
+// DeleteRuleGroup deletes a rule group via
+// /loki/api/v1/rules/{namespace}/{group}.
+func (c *Client) DeleteRuleGroup(ctx context.Context, namespace, group string) error {
+	ctx, cancel := c.withWriteDeadline(ctx)
+	defer cancel()
+
+	path := fmt.Sprintf("/loki/api/v1/rules/%s/%s", url.PathEscape(namespace), url.PathEscape(group))
+	apiEndpoint := fmt.Sprintf("%s%s", c.baseURL, path)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", apiEndpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Scope-OrgID", c.instanceID)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 == 2 {
+		return nil
+	}
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("reading request failed with status code %v: %w", res.StatusCode, err)
+	}
+	return fmt.Errorf("request failed with status code %v: %w", res.StatusCode, errors.New(string(buf)))
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+// TailOptions configures a Tail subscription.
+type TailOptions struct {
+	// Start is the time to start tailing from. Defaults to Client.Now.
+	Start time.Time
+	// DelayFor delays tailed entries by this duration, allowing slower
+	// ingesters to catch up. Sent as the `delay_for` query param in whole
+	// seconds; any fraction of a second is dropped.
+	DelayFor time.Duration
+	// Limit caps the number of entries returned per response frame.
+	Limit int
+	// PingInterval controls how often idle connections are pinged to
+	// keep them open behind proxies. Defaults to 15s.
+	PingInterval time.Duration
+	// ReconnectBackoff controls the delay between reconnect attempts.
+	// Defaults to time.Second.
+	ReconnectBackoff time.Duration
+}
+
+func (o TailOptions) withDefaults() TailOptions {
+	if o.PingInterval <= 0 {
+		o.PingInterval = 15 * time.Second
+	}
+	if o.ReconnectBackoff <= 0 {
+		o.ReconnectBackoff = time.Second
+	}
+	return o
+}
+
+// DroppedEntry describes a stream whose entries were dropped by the tailer,
+// reported alongside TailResponse.Streams.
+type DroppedEntry struct {
+	Labels    map[string]string `json:"labels"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// TailResponse is a single frame received from /loki/api/v1/tail.
+type TailResponse struct {
+	Streams        []StreamValues `json:"streams"`
+	DroppedEntries []DroppedEntry `json:"dropped_entries"`
+}
+
+// Tail subscribes to query over a WebSocket connection to
+// /loki/api/v1/tail, returning a channel of decoded frames and a channel
+// carrying the errors that ended individual connections. The error channel
+// buffers one error; further errors are dropped while it is unread. Failed
+// or dropped connections are reconnected after ReconnectBackoff, resuming
+// from the last-seen entry timestamp, for as long as ctx is not done; both
+// channels are closed only once ctx is done.
+func (c *Client) Tail(ctx context.Context, query string, opts TailOptions) (<-chan TailResponse, <-chan error, error) {
+	opts = opts.withDefaults()
+
+	start := opts.Start
+	if start.IsZero() {
+		start = c.Now
+	}
+
+	out := make(chan TailResponse)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+		defer close(errCh)
+
+		for {
+			lastSeen, err := c.tailOnce(ctx, query, start, opts, out)
+			if ctx.Err() != nil {
+				return
+			}
+			if !lastSeen.IsZero() {
+				start = lastSeen
+			}
+			if err != nil {
+				select {
+				case errCh <- err:
+				default:
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(opts.ReconnectBackoff):
+			}
+		}
+	}()
+
+	return out, errCh, nil
+}
+
+// tailURL builds the ws(s):// URL for the tail endpoint.
+func (c *Client) tailURL(query string, start time.Time, opts TailOptions) (string, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		u.Scheme = "wss"
+	case "http", "":
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + "/loki/api/v1/tail"
+
+	v := url.Values{}
+	v.Set("query", query)
+	if !start.IsZero() {
+		v.Set("start", formatTS(start))
+	}
+	if opts.DelayFor > 0 {
+		v.Set("delay_for", strconv.Itoa(int(opts.DelayFor/time.Second)))
+	}
+	if opts.Limit > 0 {
+		v.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	u.RawQuery = v.Encode()
+
+	return u.String(), nil
+}
+
+// tailOnce dials a single WebSocket connection, streams frames to out until
+// the connection drops or ctx is done, and returns the timestamp of the
+// last entry seen so reconnects can resume from there.
+func (c *Client) tailOnce(ctx context.Context, query string, start time.Time, opts TailOptions, out chan<- TailResponse) (time.Time, error) {
+	wsURL, err := c.tailURL(query, start, opts)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Dial through c.httpClient so the handshake request picks up
+	// X-Scope-OrgID, injected headers, and the configured Authenticator
+	// the same way every other request does.
+	conn, _, err := websocket.Dial(ctx, wsURL, &websocket.DialOptions{HTTPClient: c.httpClient})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("dialing tail endpoint: %w", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	pingCtx, cancelPing := context.WithCancel(ctx)
+	defer cancelPing()
+	go c.pingLoop(pingCtx, conn, opts.PingInterval)
+
+	var lastSeen time.Time
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return lastSeen, err
+		}
+
+		var resp TailResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return lastSeen, fmt.Errorf("decoding tail frame: %w", err)
+		}
+
+		for _, s := range resp.Streams {
+			for _, v := range s.Values {
+				if len(v) == 0 {
+					continue
+				}
+				if ns, err := strconv.ParseInt(v[0], 10, 64); err == nil {
+					ts := time.Unix(0, ns)
+					if ts.After(lastSeen) {
+						lastSeen = ts
+					}
+				}
+			}
+		}
+
+		select {
+		case out <- resp:
+		case <-ctx.Done():
+			return lastSeen, ctx.Err()
+		}
+	}
+}
+
+// pingLoop keeps the tail connection alive behind proxies that close idle
+// connections.
+func (c *Client) pingLoop(ctx context.Context, conn *websocket.Conn, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, interval)
+			_ = conn.Ping(pingCtx)
+			cancel()
+		}
+	}
+}
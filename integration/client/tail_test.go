@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+// TestTailReconnectResumesFromLastSeen verifies that when a tail connection
+// drops after delivering a frame, Tail reconnects and resumes from the
+// timestamp of the last entry it saw rather than the original start time.
+func TestTailReconnectResumesFromLastSeen(t *testing.T) {
+	lastSeenTS := time.Unix(100, 0)
+
+	var mu sync.Mutex
+	var starts []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, r.URL.Query().Get("start"))
+		n := len(starts)
+		mu.Unlock()
+
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+
+		if n == 1 {
+			resp := TailResponse{
+				Streams: []StreamValues{{
+					Stream: map[string]string{"job": "test"},
+					Values: [][]string{{strconv.FormatInt(lastSeenTS.UnixNano(), 10), "line one"}},
+				}},
+			}
+			data, err := json.Marshal(resp)
+			if err != nil {
+				t.Errorf("marshaling frame: %v", err)
+				return
+			}
+			if err := conn.Write(r.Context(), websocket.MessageText, data); err != nil {
+				return
+			}
+			// Drop the connection to force a reconnect.
+			return
+		}
+
+		// Second connection: hold it open until the test tears down.
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := New("tenant", "", srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	out, errCh, err := c.Tail(ctx, `{job="test"}`, TailOptions{ReconnectBackoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+
+	select {
+	case resp := <-out:
+		if len(resp.Streams) != 1 || len(resp.Streams[0].Values) != 1 {
+			t.Fatalf("unexpected frame: %+v", resp)
+		}
+	case err := <-errCh:
+		t.Fatalf("unexpected error: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for first frame")
+	}
+
+	waitDeadline := time.After(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(starts)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		select {
+		case <-waitDeadline:
+			t.Fatal("timed out waiting for reconnect")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	wantStart := strconv.FormatInt(lastSeenTS.UnixNano(), 10)
+	if starts[1] != wantStart {
+		t.Fatalf("reconnect start = %q, want %q (last-seen entry timestamp)", starts[1], wantStart)
+	}
+}
+
+// TestTailAppliesAuthenticator verifies that the WebSocket handshake goes
+// through the client's configured Authenticator, the same way plain HTTP
+// requests do.
+func TestTailAppliesAuthenticator(t *testing.T) {
+	var mu sync.Mutex
+	var gotUser, gotPass string
+	var gotOK bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		mu.Lock()
+		gotUser, gotPass, gotOK = user, pass, ok
+		mu.Unlock()
+
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := New("tenant", "supersecret", srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, _, err := c.Tail(ctx, `{job="test"}`, TailOptions{})
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+
+	deadline := time.After(time.Second)
+	for {
+		mu.Lock()
+		ok := gotOK
+		mu.Unlock()
+		if ok {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for tail handshake")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !gotOK || gotUser != "tenant" || gotPass != "supersecret" {
+		t.Fatalf("handshake basic auth = (%q, %q, ok=%v), want (\"tenant\", \"supersecret\", true)", gotUser, gotPass, gotOK)
+	}
+}
+
+// TestTailStopsOnContextCancel verifies that canceling the context passed to
+// Tail stops the reconnect loop and closes both returned channels, rather
+// than looping forever trying to reconnect.
+func TestTailStopsOnContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := New("tenant", "", srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out, errCh, err := c.Tail(ctx, `{job="test"}`, TailOptions{})
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("expected out channel to be closed after context cancel")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for out channel to close")
+	}
+
+	select {
+	case _, ok := <-errCh:
+		if ok {
+			t.Fatal("expected errCh to be closed after context cancel")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for errCh to close")
+	}
+}